@@ -0,0 +1,60 @@
+package wg
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CleanupError wraps an error returned by an AddCleanup function, so callers
+// can tell teardown failures apart from task failures in GetAllErrors
+type CleanupError struct {
+	Err error
+}
+
+// Error interface implementation
+func (e CleanupError) Error() string {
+	return fmt.Sprintf("cleanup error: %v", e.Err)
+}
+
+// Unwrap allows errors.Is/errors.As to reach the wrapped error
+func (e CleanupError) Unwrap() error {
+	return e.Err
+}
+
+// AddCleanup registers f to run after all task goroutines have finished or
+// the group has been cancelled/timed-out, even if that happened via a panic
+func (wg *waitGroup) AddCleanup(f WaitgroupFunc) WaitGroup {
+	wg.cleanups = append(wg.cleanups, f)
+	return wg
+}
+
+// SetCleanupTimeout defines the timeout given to registered cleanups,
+// separate from SetTimeout
+func (wg *waitGroup) SetCleanupTimeout(t time.Duration) WaitGroup {
+	wg.cleanupTimeout = &t
+	return wg
+}
+
+// runCleanups runs registered cleanups in reverse registration order, like
+// deferred functions, against a fresh context so a cancelled/timed-out
+// parent context doesn't abort teardown
+func (wg *waitGroup) runCleanups() {
+	if len(wg.cleanups) == 0 {
+		return
+	}
+
+	timeout := defaultMaxTimeout
+	if wg.cleanupTimeout != nil && *wg.cleanupTimeout != 0 {
+		timeout = *wg.cleanupTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for i := len(wg.cleanups) - 1; i >= 0; i-- {
+		if err, _ := runRecovered(ctx, wg.cleanups[i]); err != nil {
+			wg.addError(CleanupError{Err: err})
+		}
+	}
+}
@@ -2,18 +2,23 @@ package wg
 
 import "time"
 
+// Status represents the observable state of a WaitGroup
+type Status int32
+
 const (
-	// statusIdle means that WaitGroup did not run yet
-	statusIdle int = iota
-	// statusSuccess means successful execution of all tasks
-	statusSuccess
-	// statusTimeout means that job was broken by timeout
-	statusTimeout
-	// statusCaneled means that job was broken by context.CancelFunc call
-	statusCaneled
-	// statusError means that job was broken by error in one task (if stopOnError is true)
-	statusError
+	// StatusIdle means that WaitGroup did not run yet
+	StatusIdle Status = iota
+	// StatusSuccess means successful execution of all tasks
+	StatusSuccess
+	// StatusTimeout means that job was broken by timeout
+	StatusTimeout
+	// StatusCancelled means that job was broken by context.CancelFunc call
+	StatusCancelled
+	// StatusError means that a task returned an error; the job is broken by it only if stopOnError is true
+	StatusError
+)
 
+const (
 	errTimeoutMessage = "Wait group timeout after %v"
 	stackBufferSize   = 1000
 	defaultMaxTimeout = time.Second * 15
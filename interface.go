@@ -29,22 +29,89 @@ type WaitGroup interface {
 	// *must be called before Start()
 	SetStopOnError(flag bool) WaitGroup
 
+	// SetMaxConcurrency caps the number of simultaneously running task
+	// goroutines; n <= 0 means unbounded
+	// *must be called before Start()
+	SetMaxConcurrency(n int) WaitGroup
+
+	// SetRetry makes every task queued before Start() re-invoked up to
+	// attempts times (sleeping backoff(attempt) between tries) when it
+	// returns an error; tasks launched by Go during a run are not retried
+	// *must be called before Start()
+	SetRetry(attempts int, backoff BackoffFunc) WaitGroup
+
+	// AddWithRetry adds a task that is re-invoked up to attempts times
+	// (sleeping backoff(attempt) between tries) when it returns an error,
+	// whether or not SetRetry is set. If SetRetry is also set with more
+	// than one attempt, its policy wraps this task again, so f can run up
+	// to attempts times the SetRetry attempts
+	// *must be called before Start()
+	AddWithRetry(attempts int, backoff BackoffFunc, f WaitgroupFunc) WaitGroup
+
+	// AddKeyed adds a task under key, deduplicating concurrent submissions
+	// that share the same key: only the first submitter runs f, the rest
+	// block and share its result
+	// *must be called before Start()
+	AddKeyed(key string, f WaitgroupFunc) WaitGroup
+
+	// AddCleanup registers f to run after all task goroutines have
+	// finished or the group has been cancelled/timed-out, even if that
+	// happened via a panic
+	// *must be called before Start()
+	AddCleanup(f WaitgroupFunc) WaitGroup
+
+	// SetCleanupTimeout defines the timeout given to registered cleanups,
+	// separate from SetTimeout
+	// *must be called before Start()
+	SetCleanupTimeout(timeout time.Duration) WaitGroup
+
 	// Start runs tasks in separate goroutines
 	Start() WaitGroup
 
+	// StartStream runs tasks in separate goroutines and streams a
+	// TaskResult for every task as soon as it finishes, closing the
+	// channel once all tasks are done or the group is cancelled/timed-out
+	StartStream() <-chan TaskResult
+
+	// Go queues f and, if Start has already been called and is still
+	// running, launches it right away instead of waiting for a future
+	// Start() call; once the group has finished, it only appends f to the
+	// queue, as Add does. A group whose last run ended with StatusSuccess
+	// returns from Start right away, so f queued after such a run does not
+	// run
+	Go(f WaitgroupFunc) WaitGroup
+
+	// Wait runs the queued tasks via Start, so it blocks exactly as long as
+	// Start does, and returns the first error caught by the execution
+	// process, mirroring errgroup.Wait
+	Wait() error
+
 	// GetLastError returns last error that caught by execution process
 	GetLastError() error
 
 	// GetAllErrors returns all errors that caught by execution process
 	GetAllErrors() []error
 
-	// Reset performs cleanup task queue and reset state
-	Reset() WaitGroup
+	// Status returns the current observable state of the WaitGroup
+	Status() Status
+
+	// Reset performs cleanup task queue and reset state. It returns
+	// ErrGroupBusy and leaves the group untouched while a run started by
+	// Start or StartStream is still dispatching tasks; task goroutines left
+	// behind by a timeout, cancel or stopOnError break are not waited for
+	Reset() error
 }
 
 // WaitgroupFunc goroutine func to be added in queue
 type WaitgroupFunc func(context.Context) error
 
+// TaskResult carries the outcome of a single task run via StartStream
+type TaskResult struct {
+	Index    int
+	Err      error
+	Duration time.Duration
+}
+
 // ErrorTimeout error on timeout
 type ErrorTimeout time.Duration
 
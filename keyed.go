@@ -0,0 +1,66 @@
+package wg
+
+import (
+	"context"
+	"sync"
+)
+
+// call tracks a single in-flight execution of a keyed task so that
+// duplicate submissions of the same key can share its result
+type call struct {
+	wg  sync.WaitGroup
+	err error
+}
+
+// AddKeyed adds a task under key. If a task for the same key is already
+// in-flight (added earlier in the same batch, or still running in a
+// long-lived group), the duplicate submission blocks until the in-flight
+// call finishes and shares its error instead of running f again.
+//
+// The key is reserved synchronously, at AddKeyed's call time, rather than
+// when the task actually runs: with SetMaxConcurrency in play, queued tasks
+// can sit behind the semaphore long enough for an earlier in-flight call to
+// finish and clear its entry, which would otherwise defeat the dedup.
+func (wg *waitGroup) AddKeyed(key string, f WaitgroupFunc) WaitGroup {
+	wg.keyedMu.Lock()
+	if wg.keyedCalls == nil {
+		wg.keyedCalls = make(map[string]*call)
+	}
+
+	if c, ok := wg.keyedCalls[key]; ok {
+		wg.keyedMu.Unlock()
+		return wg.Add(wg.waitKeyed(c))
+	}
+
+	c := new(call)
+	c.wg.Add(1)
+	wg.keyedCalls[key] = c
+	wg.keyedMu.Unlock()
+
+	return wg.Add(wg.runKeyed(key, c, f))
+}
+
+// runKeyed runs f for the first submitter of key, then clears the reservation
+// and releases any duplicates waiting on c
+func (wg *waitGroup) runKeyed(key string, c *call, f WaitgroupFunc) WaitgroupFunc {
+	return func(ctx context.Context) error {
+		c.err = f(ctx)
+
+		wg.keyedMu.Lock()
+		delete(wg.keyedCalls, key)
+		wg.keyedMu.Unlock()
+
+		c.wg.Done()
+
+		return c.err
+	}
+}
+
+// waitKeyed blocks a duplicate submission until the in-flight call for its
+// key finishes, then shares its error instead of running its own f
+func (wg *waitGroup) waitKeyed(c *call) WaitgroupFunc {
+	return func(ctx context.Context) error {
+		c.wg.Wait()
+		return c.err
+	}
+}
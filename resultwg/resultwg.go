@@ -0,0 +1,94 @@
+// Package resultwg adds typed result collection on top of wg.WaitGroup, so
+// callers no longer need to smuggle output through captured channels.
+package resultwg
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/savalin/wg"
+)
+
+// ResultFunc is a task that produces a typed result alongside the usual error
+type ResultFunc[T any] func(context.Context) (T, error)
+
+// ResultGroup runs ResultFunc tasks on top of wg.WaitGroup and collects their
+// typed results
+type ResultGroup[T any] struct {
+	wg wg.WaitGroup
+
+	mu      sync.Mutex
+	results []T
+}
+
+// New returns new empty ResultGroup
+func New[T any]() *ResultGroup[T] {
+	return &ResultGroup[T]{wg: wg.New()}
+}
+
+// WithContext make result group work with context timeout and Done
+// *must be called before Start()
+func (rg *ResultGroup[T]) WithContext(ctx context.Context) *ResultGroup[T] {
+	rg.wg.WithContext(ctx)
+	return rg
+}
+
+// SetTimeout defines timeout for all tasks
+// *must be called before Start()
+func (rg *ResultGroup[T]) SetTimeout(timeout time.Duration) *ResultGroup[T] {
+	rg.wg.SetTimeout(timeout)
+	return rg
+}
+
+// SetStopOnError make result group stops if any task returns error
+// *must be called before Start()
+func (rg *ResultGroup[T]) SetStopOnError(flag bool) *ResultGroup[T] {
+	rg.wg.SetStopOnError(flag)
+	return rg
+}
+
+// Add adds new task in result group
+// *must be called before Start()
+func (rg *ResultGroup[T]) Add(f ResultFunc[T]) *ResultGroup[T] {
+	rg.wg.Add(func(ctx context.Context) error {
+		res, err := f(ctx)
+		if err != nil {
+			return err
+		}
+
+		rg.mu.Lock()
+		rg.results = append(rg.results, res)
+		rg.mu.Unlock()
+
+		return nil
+	})
+
+	return rg
+}
+
+// Start runs tasks in separate goroutines
+func (rg *ResultGroup[T]) Start() *ResultGroup[T] {
+	rg.wg.Start()
+	return rg
+}
+
+// Wait runs the queued tasks via Start, so it blocks exactly as long as Start
+// does, and returns the first error caught by the execution process,
+// mirroring errgroup.Wait
+func (rg *ResultGroup[T]) Wait() error {
+	return rg.wg.Wait()
+}
+
+// Results returns the collected typed results of all successfully finished
+// tasks. Order is not guaranteed to match Add order since tasks run
+// concurrently.
+func (rg *ResultGroup[T]) Results() []T {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	out := make([]T, len(rg.results))
+	copy(out, rg.results)
+
+	return out
+}
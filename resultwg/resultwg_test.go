@@ -0,0 +1,68 @@
+package resultwg
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func double(n int) ResultFunc[int] {
+	return func(context.Context) (int, error) {
+		return n * 2, nil
+	}
+}
+
+func failing(context.Context) (int, error) {
+	return 0, errors.New("Test error")
+}
+
+// Test_ResultGroup_Success test for typed result collection
+func Test_ResultGroup_Success(t *testing.T) {
+	rg := New[int]()
+
+	rg.Add(double(1))
+	rg.Add(double(2))
+	rg.Add(double(3))
+
+	if err := rg.Wait(); err != nil {
+		t.Errorf("ResultGroup shouldn`t return an error, got %v", err)
+	}
+
+	results := rg.Results()
+	sort.Ints(results)
+
+	if want := []int{2, 4, 6}; !equalInts(results, want) {
+		t.Errorf("Expected results %v, got %v", want, results)
+	}
+}
+
+// Test_ResultGroup_Error test that a failing task doesn't contribute a result
+func Test_ResultGroup_Error(t *testing.T) {
+	rg := New[int]()
+
+	rg.Add(double(1))
+	rg.Add(failing)
+
+	if err := rg.Wait(); err == nil {
+		t.Error("ResultGroup should return the error from the failing task")
+	}
+
+	if results := rg.Results(); len(results) != 1 {
+		t.Errorf("Expected one result from the successful task, got %v", results)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
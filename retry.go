@@ -0,0 +1,95 @@
+package wg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+// BackoffFunc computes the delay to wait before retry attempt n (1-indexed)
+type BackoffFunc func(attempt int) time.Duration
+
+// ConstantBackoff returns a BackoffFunc that always waits d
+func ConstantBackoff(d time.Duration) BackoffFunc {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff returns a BackoffFunc that doubles base on every
+// attempt, capped at max
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := base * time.Duration(int64(1)<<uint(attempt-1))
+		if d <= 0 || d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// JitteredBackoff wraps backoff and adds up to 50% random jitter on top of
+// the delay it computes
+func JitteredBackoff(backoff BackoffFunc) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := backoff(attempt)
+		return d + time.Duration(rand.Int63n(int64(d)/2+1))
+	}
+}
+
+// retryTask wraps f so that it is re-invoked up to attempts times, sleeping
+// backoff(attempt) between tries and honoring ctx.Done during the sleep. A
+// panic inside f counts as a failed attempt rather than aborting the retry
+// loop, unless stopOnError is true, in which case it is returned right away.
+// attempts <= 0 is treated as 1, so f always runs at least once, matching
+// Add's normal semantics.
+func (wg *waitGroup) retryTask(attempts int, backoff BackoffFunc, f WaitgroupFunc) WaitgroupFunc {
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	return func(ctx context.Context) error {
+		var lastErr error
+
+		for attempt := 1; attempt <= attempts; attempt++ {
+			err, panicked := runRecovered(ctx, f)
+			if err == nil {
+				return nil
+			}
+
+			lastErr = err
+			if panicked && wg.stopOnError {
+				return lastErr
+			}
+
+			if attempt == attempts {
+				break
+			}
+
+			select {
+			case <-time.After(backoff(attempt)):
+			case <-ctx.Done():
+				return lastErr
+			}
+		}
+
+		return lastErr
+	}
+}
+
+// runRecovered runs f, converting a panic into an error the same way wg.do
+// does for non-retried tasks
+func runRecovered(ctx context.Context, f WaitgroupFunc) (err error, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, stackBufferSize)
+			count := runtime.Stack(buf, false)
+			err = fmt.Errorf("Panic handeled\n%v\n%s", r, buf[:count])
+			panicked = true
+		}
+	}()
+
+	return f(ctx), false
+}
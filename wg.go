@@ -2,31 +2,56 @@ package wg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrGroupBusy is returned by Reset when the group still has tasks running
+var ErrGroupBusy = errors.New("wg: group is still running")
+
+// ErrTaskNotStarted is the TaskResult.Err of a task that was still queued
+// behind SetMaxConcurrency when the group was cancelled or timed out, so it
+// never actually ran
+var ErrTaskNotStarted = errors.New("wg: task was cancelled before it started")
+
 // waitGroup enhanced wait group struct
 type waitGroup struct {
-	waitGroupStatus
+	status atomic.Int32
+
 	ctx      context.Context
 	receiver chan WaitgroupFunc
 	sender   chan WaitgroupFunc
 
+	errorsMu    sync.Mutex
 	errors      []error
 	stopOnError bool
 
+	// bufMu guards stackBuffer and running together, and is held across
+	// both the close(sender)/running flip in runLoop and the
+	// running-check+send in Go, so the two can never race: Go never sees
+	// running still true a moment before sender is closed under it
+	bufMu       sync.Mutex
 	stackBuffer []WaitgroupFunc
+	running     bool
 	capacity    uint32
-	length      int
+	length      atomic.Int32
 	timeout     *time.Duration
-}
 
-type waitGroupStatus struct {
-	status     int
-	statusLock sync.RWMutex
+	maxConcurrency int
+	sem            chan struct{}
+
+	retryAttempts int
+	retryBackoff  BackoffFunc
+
+	keyedMu    sync.Mutex
+	keyedCalls map[string]*call
+
+	cleanups       []WaitgroupFunc
+	cleanupTimeout *time.Duration
 }
 
 // WithContext make wait group work with context timeout and Done
@@ -37,7 +62,10 @@ func (wg *waitGroup) WithContext(ctx context.Context) WaitGroup {
 
 // Add adds new task in waitgroup
 func (wg *waitGroup) Add(f ...WaitgroupFunc) WaitGroup {
+	wg.bufMu.Lock()
 	wg.stackBuffer = append(wg.stackBuffer, f...)
+	wg.bufMu.Unlock()
+
 	return wg
 }
 
@@ -61,91 +89,221 @@ func (wg *waitGroup) SetCapacity(c int) WaitGroup {
 	return wg
 }
 
-// Start runs tasks in separate goroutines
+// SetMaxConcurrency caps the number of simultaneously running task
+// goroutines; n <= 0 means unbounded
+func (wg *waitGroup) SetMaxConcurrency(n int) WaitGroup {
+	wg.maxConcurrency = n
+	return wg
+}
+
+// SetRetry makes every task queued before Start() re-invoked up to attempts
+// times (sleeping backoff(attempt) between tries) when it returns an error;
+// tasks launched by Go during a run are not retried
+func (wg *waitGroup) SetRetry(attempts int, backoff BackoffFunc) WaitGroup {
+	wg.retryAttempts = attempts
+	wg.retryBackoff = backoff
+	return wg
+}
+
+// AddWithRetry adds a task that is re-invoked up to attempts times (sleeping
+// backoff(attempt) between tries) when it returns an error, whether or not
+// SetRetry is set. If SetRetry is also set with more than one attempt, its
+// policy wraps this task again, so f can run up to attempts times the
+// SetRetry attempts
+func (wg *waitGroup) AddWithRetry(attempts int, backoff BackoffFunc, f WaitgroupFunc) WaitGroup {
+	return wg.Add(wg.retryTask(attempts, backoff, f))
+}
+
+// Start runs tasks in separate goroutines and blocks until they all finish
+// or the group is cancelled/timed-out
 func (wg *waitGroup) Start() WaitGroup {
-	if wg.checkStatus(statusSuccess) {
-		return wg
+	for range wg.StartStream() {
+		// drain the stream to preserve the collect-then-return behavior
+	}
+
+	return wg
+}
+
+// StartStream runs tasks in separate goroutines and streams a TaskResult
+// for every task as soon as it finishes, closing the channel once all
+// tasks are done or the group is cancelled/timed-out
+func (wg *waitGroup) StartStream() <-chan TaskResult {
+	stream := make(chan TaskResult)
+
+	if wg.checkStatus(StatusSuccess) {
+		close(stream)
+		return stream
 	}
 
 	wg.init()
 
-	if wg.length > 0 {
-		var (
-			failed = make(chan error, wg.length)
-			done   = make(chan struct{}, wg.length)
-			wgDone = make(chan struct{})
+	go wg.runLoop(stream)
 
-			cancel    context.CancelFunc
-			startTime = time.Now()
-			timeout   = defaultMaxTimeout
-		)
+	return stream
+}
+
+func (wg *waitGroup) runLoop(stream chan<- TaskResult) {
+	defer close(stream)
+	defer wg.runCleanups()
+
+	if wg.length.Load() <= 0 {
+		wg.bufMu.Lock()
+		wg.running = false
+		wg.bufMu.Unlock()
+		return
+	}
+
+	var (
+		completions = make(chan TaskResult, wg.length.Load())
+		wgDone      = make(chan struct{})
 
-		if wg.timeout != nil && *wg.timeout != 0 {
-			startTime = time.Now()
-			timeout = *wg.timeout
+		cancel    context.CancelFunc
+		startTime = time.Now()
+		timeout   = defaultMaxTimeout
+
+		index = 0
+	)
+
+	if wg.timeout != nil && *wg.timeout != 0 {
+		startTime = time.Now()
+		timeout = *wg.timeout
+	}
+
+	wg.ctx, cancel = context.WithTimeout(wg.ctx, timeout)
+	defer cancel()
+
+	go func() {
+		for f := range wg.sender {
+			select {
+			case wg.receiver <- f:
+				// successfully sent a func to the execution queue
+			case <-wgDone:
+				return
+			}
 		}
+	}()
+
+ForLoop:
+	for wg.length.Load() > 0 {
+		select {
 
-		wg.ctx, cancel = context.WithTimeout(wg.ctx, timeout)
-		defer cancel()
+		// If we have functions in queue to be ran
+		case f := <-wg.receiver:
+			idx := index
+			index++
 
-		go func() {
-			for f := range wg.sender {
+			if wg.sem != nil {
 				select {
-				case wg.receiver <- f:
-					// successfully sent a func to the execution queue
-				case <-wgDone:
-					return
+				case wg.sem <- struct{}{}:
+				case <-wg.ctx.Done():
+					completions <- TaskResult{Index: idx, Err: ErrTaskNotStarted}
+					continue ForLoop
 				}
 			}
-		}()
 
-	ForLoop:
-		for wg.length > 0 {
-			select {
+			go func(f WaitgroupFunc, idx int) {
+				if wg.sem != nil {
+					defer func() { <-wg.sem }()
+				}
 
-			// If we have functions in queue to be ran
-			case f := <-wg.receiver:
-				go func(f WaitgroupFunc, failed chan<- error, done chan<- struct{}) {
-					if wg.stopOnError {
-						wg.do(f, failed, done, true)
-						return
-					}
-					wg.do(f, failed, done, false)
-
-				}(f, failed, done)
-
-				// If we got en error returned from some goroutine
-			case err := <-failed:
-				wg.errors = append(wg.errors, err)
-				wg.length--
-				wg.setStatus(statusError)
 				if wg.stopOnError {
-					break ForLoop
+					wg.do(f, idx, completions, true)
+					return
 				}
+				wg.do(f, idx, completions, false)
 
-				// If all working goroutines are successfully finished
-			case <-done:
-				wg.length--
-
-				// If context deadline exceeded
-			case <-wg.ctx.Done():
-				if wg.ctx.Err().Error() == context.Canceled.Error() {
-					wg.setStatus(statusCancelled)
-				} else if deadlineTime, ok := wg.ctx.Deadline(); ok {
-					wg.errors = append(wg.errors, ErrorTimeout(deadlineTime.Sub(startTime)))
-					wg.setStatus(statusTimeout)
-				}
+			}(f, idx)
+
+			// If a task finished, successfully or not
+		case res := <-completions:
+			wg.length.Add(-1)
+
+			if res.Err != nil {
+				wg.addError(res.Err)
+				wg.setStatus(StatusError)
+			}
+
+			stream <- res
+
+			if res.Err != nil && wg.stopOnError {
 				break ForLoop
 			}
+
+			// If context deadline exceeded
+		case <-wg.ctx.Done():
+			if wg.ctx.Err().Error() == context.Canceled.Error() {
+				wg.setStatus(StatusCancelled)
+			} else if deadlineTime, ok := wg.ctx.Deadline(); ok {
+				wg.addError(ErrorTimeout(deadlineTime.Sub(startTime)))
+				wg.setStatus(StatusTimeout)
+			}
+			break ForLoop
 		}
+	}
+
+	// ForLoop can break on <-wg.ctx.Done() in the same instant a result was
+	// buffered into completions (e.g. ErrTaskNotStarted for a task that lost
+	// the sem race against ctx just above), and select doesn't favor one
+	// ready case over the other. Drain whatever is already sitting in
+	// completions so a result is never silently dropped.
+Drain:
+	for {
+		select {
+		case res := <-completions:
+			wg.length.Add(-1)
+
+			if res.Err != nil {
+				wg.addError(res.Err)
+			}
 
-		close(wgDone)
-		close(wg.sender)
+			stream <- res
+		default:
+			break Drain
+		}
 	}
 
+	close(wgDone)
+
+	wg.bufMu.Lock()
+	close(wg.sender)
+	wg.running = false
+	wg.bufMu.Unlock()
+}
+
+// Go queues f and, if Start has already been called and is still running,
+// launches it right away instead of waiting for a future Start() call; once
+// the group has finished, it only appends f to the queue, as Add does, since
+// the previous run's sender channel is now closed. A group whose last run
+// ended with StatusSuccess returns from Start right away, so f queued after
+// such a run does not run
+func (wg *waitGroup) Go(f WaitgroupFunc) WaitGroup {
+	wg.bufMu.Lock()
+	defer wg.bufMu.Unlock()
+
+	if !wg.running {
+		wg.stackBuffer = append(wg.stackBuffer, f)
+		return wg
+	}
+
+	wg.length.Add(1)
+	wg.sender <- f
+
 	return wg
 }
 
+// Wait runs the queued tasks via Start, so it blocks exactly as long as Start
+// does, and returns the first error caught by the execution process,
+// mirroring errgroup.Wait
+func (wg *waitGroup) Wait() error {
+	wg.Start()
+
+	if errs := wg.GetAllErrors(); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
 // GetCapacity defines tasks channel capacity
 func (wg *waitGroup) GetCapacity() int {
 	return int(wg.capacity)
@@ -153,6 +311,9 @@ func (wg *waitGroup) GetCapacity() int {
 
 // GetLastError returns last error that caught by execution process
 func (wg *waitGroup) GetLastError() error {
+	wg.errorsMu.Lock()
+	defer wg.errorsMu.Unlock()
+
 	if l := len(wg.errors); l > 0 {
 		return wg.errors[l-1]
 	}
@@ -161,85 +322,141 @@ func (wg *waitGroup) GetLastError() error {
 
 // GetAllErrors returns all errors that caught by execution process
 func (wg *waitGroup) GetAllErrors() []error {
-	return wg.errors
+	wg.errorsMu.Lock()
+	defer wg.errorsMu.Unlock()
+
+	errs := make([]error, len(wg.errors))
+	copy(errs, wg.errors)
+
+	return errs
+}
+
+func (wg *waitGroup) addError(err error) {
+	wg.errorsMu.Lock()
+	wg.errors = append(wg.errors, err)
+	wg.errorsMu.Unlock()
 }
 
-// Reset performs cleanup task queue and reset state
-func (wg *waitGroup) Reset() WaitGroup {
+// Status returns the current observable state of the WaitGroup
+func (wg *waitGroup) Status() Status {
+	return Status(wg.status.Load())
+}
+
+// Reset performs cleanup task queue and reset state. It returns
+// ErrGroupBusy and leaves the group untouched while a run started by Start
+// or StartStream is still dispatching tasks; task goroutines left behind by
+// a timeout, cancel or stopOnError break are not waited for
+func (wg *waitGroup) Reset() error {
+	wg.bufMu.Lock()
+	if wg.running {
+		wg.bufMu.Unlock()
+		return ErrGroupBusy
+	}
+
 	wg.stackBuffer = []WaitgroupFunc{}
 	wg.receiver = nil
 	wg.sender = nil
+	wg.bufMu.Unlock()
+
+	wg.length.Store(0)
 	wg.timeout = nil
 	wg.stopOnError = false
-	wg.setStatus(statusIdle)
+	wg.setStatus(StatusIdle)
+
+	wg.errorsMu.Lock()
 	wg.errors = []error{}
+	wg.errorsMu.Unlock()
+
 	wg.ctx = nil
+	wg.keyedCalls = nil
+	wg.cleanups = nil
+	wg.cleanupTimeout = nil
 
-	return wg
+	return nil
 }
 
 func (wg *waitGroup) init() {
-	wg.setStatus(statusSuccess)
+	wg.setStatus(StatusSuccess)
 
 	if wg.ctx == nil {
 		wg.ctx = context.Background()
 	}
 
-	wg.length = len(wg.stackBuffer)
-	cap := wg.length
+	wg.bufMu.Lock()
+	defer wg.bufMu.Unlock()
+
+	length := len(wg.stackBuffer)
+	wg.length.Store(int32(length))
+
+	cap := length
 	if c := wg.GetCapacity(); c > 0 {
 		cap = c
 	}
 
+	if wg.retryAttempts > 1 {
+		for i, f := range wg.stackBuffer {
+			wg.stackBuffer[i] = wg.retryTask(wg.retryAttempts, wg.retryBackoff, f)
+		}
+	}
+
 	wg.receiver = make(chan WaitgroupFunc, cap)
-	wg.sender = make(chan WaitgroupFunc, wg.length)
+	wg.sender = make(chan WaitgroupFunc, length)
+
+	if wg.maxConcurrency > 0 {
+		wg.sem = make(chan struct{}, wg.maxConcurrency)
+	} else {
+		wg.sem = nil
+	}
 	for _, f := range wg.stackBuffer {
 		wg.sender <- f
 	}
+
+	wg.running = true
 }
 
-func (wg *waitGroup) do(f WaitgroupFunc, failed chan<- error, done chan<- struct{}, stopOnError bool) {
+func (wg *waitGroup) do(f WaitgroupFunc, index int, completions chan<- TaskResult, stopOnError bool) {
+	start := time.Now()
+
 	// Handle panic and pack it into stdlib error
 	defer func() {
 		if r := recover(); r != nil {
 			buf := make([]byte, stackBufferSize)
 			count := runtime.Stack(buf, false)
-			failed <- fmt.Errorf("Panic handeled\n%v\n%s", r, buf[:count])
+			completions <- TaskResult{
+				Index:    index,
+				Err:      fmt.Errorf("Panic handeled\n%v\n%s", r, buf[:count]),
+				Duration: time.Since(start),
+			}
 		}
 	}()
 
 	// Check stop on error
-	if stopOnError && !wg.checkStatus(statusSuccess) {
+	if stopOnError && !wg.checkStatus(StatusSuccess) {
 		// If some other goroutine get an error
-		done <- struct{}{}
+		completions <- TaskResult{Index: index, Duration: time.Since(start)}
 		return
 	}
 
 	if err := f(wg.ctx); err != nil {
-		failed <- err
+		completions <- TaskResult{Index: index, Err: err, Duration: time.Since(start)}
 		return
 	}
 
-	done <- struct{}{}
+	completions <- TaskResult{Index: index, Duration: time.Since(start)}
 }
 
-func (wg *waitGroup) setStatus(status int) {
-	if status < statusIdle || status > statusError {
+func (wg *waitGroup) setStatus(status Status) {
+	if status < StatusIdle || status > StatusError {
 		return
 	}
 
-	wg.statusLock.Lock()
-	wg.status = status
-	wg.statusLock.Unlock()
+	wg.status.Store(int32(status))
 }
 
-func (wg *waitGroup) checkStatus(status int) bool {
-	if status < statusIdle || status > statusError {
+func (wg *waitGroup) checkStatus(status Status) bool {
+	if status < StatusIdle || status > StatusError {
 		return false
 	}
 
-	wg.statusLock.RLock()
-	defer wg.statusLock.RUnlock()
-
-	return wg.status == status
+	return wg.status.Load() == int32(status)
 }
@@ -4,15 +4,20 @@ import (
 	"context"
 	"errors"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+// count is shared by every concurrently running task helper below, so it's
+// mutated atomically to keep the helpers themselves from tripping -race and
+// burying real races in wg's own code under fixture noise
 var count int64
 
 func slowFunc(ctx context.Context) error {
 	for i := 0; i < 10000000; i++ {
-		count *= int64(i)
+		atomic.AddInt64(&count, int64(i))
 	}
 
 	return nil
@@ -25,7 +30,7 @@ func fastFunc(context.Context) error {
 
 func errorFunc(context.Context) error {
 	for i := 0; i < 10000; i++ {
-		count *= int64(i)
+		atomic.AddInt64(&count, int64(i))
 	}
 
 	return errors.New("Test error")
@@ -76,7 +81,7 @@ func Test_WaitGroup_Timeout_Context(t *testing.T) {
 	defer cancel()
 
 	wg.WithContext(ctx).Start()
-	if wg.status != statusTimeout {
+	if wg.Status() != StatusTimeout {
 		t.Error("WaitGroup should stops by timeout!")
 	}
 
@@ -128,7 +133,7 @@ func Test_WaitGroup_Success(t *testing.T) {
 		t.Errorf("WaitGroup result should be 'success'! But got errors %v", errs)
 	}
 
-	if wg.status != statusSuccess {
+	if wg.Status() != StatusSuccess {
 		t.Error("WaitGroup result should be 'success'!")
 	}
 }
@@ -151,7 +156,7 @@ func Test_WaitGroup_Success_WithCapacity(t *testing.T) {
 		t.Errorf("WaitGroup result should be 'success'! But got errors %v", errs)
 	}
 
-	if wg.status != statusSuccess {
+	if wg.Status() != StatusSuccess {
 		t.Error("WaitGroup result should be 'success'!")
 	}
 }
@@ -181,7 +186,7 @@ func Test_WaitGroup_Cancel_Success(t *testing.T) {
 		t.Errorf("WaitGroup result should be 'success'! But got errors %v", errs)
 	}
 
-	if wg.status != statusCancelled {
+	if wg.Status() != StatusCancelled {
 		t.Error("WaitGroup result should be 'canelled'!")
 	}
 }
@@ -212,7 +217,7 @@ func Test_WaitGroup_CancelWithCapacity_Success(t *testing.T) {
 		t.Errorf("WaitGroup result should be 'success'! But got errors %v", errs)
 	}
 
-	if wg.status != statusCancelled {
+	if wg.Status() != StatusCancelled {
 		t.Error("WaitGroup result should be 'success'!")
 	}
 }
@@ -226,7 +231,7 @@ func Test_WaitGroup_PanicError(t *testing.T) {
 
 	wg.SetStopOnError(true).Start()
 
-	if wg.status != statusError {
+	if wg.Status() != StatusError {
 		t.Error("WaitGroup result should be 'error'!")
 	}
 
@@ -257,7 +262,7 @@ func Test_WaitGroup_StopOnErrorPanic(t *testing.T) {
 	wg.SetStopOnError(true).
 		Start()
 
-	if wg.status != statusError {
+	if wg.Status() != StatusError {
 		t.Error("WaitGroup result should be 'error'!")
 	}
 }
@@ -310,8 +315,8 @@ func Test_WaitGroup_AddTimeout(t *testing.T) {
 	wg.Add(fastFunc, fastFunc, fastFunc, slowFunc, slowFunc, slowFunc)
 	wg.SetTimeout(time.Nanosecond * 10).SetStopOnError(true).Start()
 
-	if wg.status != statusTimeout {
-		t.Error("WaitGroup should stops by timeout!", wg.status)
+	if wg.Status() != StatusTimeout {
+		t.Error("WaitGroup should stops by timeout!", wg.Status())
 	}
 
 	err := wg.GetLastError()
@@ -331,8 +336,8 @@ func Test_WaitGroup_AddTimeoutSuccess(t *testing.T) {
 	wg.Add(fastFunc, fastFunc, fastFunc)
 	wg.SetTimeout(time.Second * 10).SetStopOnError(true).Start()
 
-	if wg.status != statusSuccess {
-		t.Error("WaitGroup shouldn`t stops by timeout!", wg.status)
+	if wg.Status() != StatusSuccess {
+		t.Error("WaitGroup shouldn`t stops by timeout!", wg.Status())
 	}
 
 	err := wg.GetLastError()
@@ -414,7 +419,7 @@ func Test_WaitGroup_Reset(t *testing.T) {
 	wg.Start()
 
 	wg.Reset()
-	if wg.status != statusIdle {
+	if wg.Status() != StatusIdle {
 		t.Error("Cleaned wg should have idle status")
 	}
 
@@ -425,6 +430,90 @@ func Test_WaitGroup_Reset(t *testing.T) {
 	}
 }
 
+// Test_WaitGroup_ResetWhileRunning test that Reset refuses to run against
+// a busy group and reports ErrGroupBusy instead of corrupting its state
+func Test_WaitGroup_ResetWhileRunning(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(slowFunc, slowFunc, slowFunc)
+
+	chDone := make(chan struct{})
+	go func() {
+		wg.Start()
+		close(chDone)
+	}()
+
+	time.Sleep(5 * time.Microsecond)
+
+	if err := wg.Reset(); err != ErrGroupBusy {
+		t.Errorf("Expected ErrGroupBusy while the group is running, got %v", err)
+	}
+
+	<-chDone
+
+	if err := wg.Reset(); err != nil {
+		t.Errorf("Reset shouldn`t fail once the group has finished, got %v", err)
+	}
+}
+
+// Test_WaitGroup_AddAfterStart test that adding tasks while a previous
+// Start() is still running doesn't trip the race detector
+func Test_WaitGroup_AddAfterStart(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(slowFunc, slowFunc)
+
+	chDone := make(chan struct{})
+	go func() {
+		wg.Start()
+		close(chDone)
+	}()
+
+	wg.Add(fastFunc)
+
+	<-chDone
+}
+
+// Test_WaitGroup_RaceMisuse stresses concurrent Reset and GetAllErrors
+// calls against a running group, analogous to sync.TestWaitGroupMisuse.
+// Run with -race to confirm the shared state is properly guarded.
+func Test_WaitGroup_RaceMisuse(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(slowFunc, slowFunc, errorFunc)
+
+	chDone := make(chan struct{})
+	go func() {
+		wg.Start()
+		close(chDone)
+	}()
+
+	var stressWg sync.WaitGroup
+
+	stressWg.Add(1)
+	go func() {
+		defer stressWg.Done()
+		for i := 0; i < 5; i++ {
+			if err := wg.Reset(); err != nil && err != ErrGroupBusy {
+				t.Errorf("Unexpected Reset error: %v", err)
+			}
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		stressWg.Add(1)
+		go func() {
+			defer stressWg.Done()
+			_ = wg.GetAllErrors()
+			_ = wg.GetLastError()
+			_ = wg.Status()
+		}()
+	}
+
+	stressWg.Wait()
+	<-chDone
+}
+
 // Test_WaitGroup_DoubleStart test
 func Test_WaitGroup_DoubleStart(t *testing.T) {
 	var wg1, wg2 waitGroup
@@ -455,6 +544,438 @@ func Test_WaitGroup_DoubleStart(t *testing.T) {
 	}
 }
 
+// Test_WaitGroup_MaxConcurrency test that running goroutines stay bounded
+func Test_WaitGroup_MaxConcurrency(t *testing.T) {
+	var wg waitGroup
+
+	const maxConcurrency = 3
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+	)
+
+	track := func(context.Context) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return nil
+	}
+
+	for i := 0; i < 20; i++ {
+		wg.Add(track)
+	}
+
+	wg.SetMaxConcurrency(maxConcurrency).Start()
+
+	if peak > maxConcurrency {
+		t.Errorf("Peak concurrency %d exceeded max %d", peak, maxConcurrency)
+	}
+}
+
+// Test_WaitGroup_MaxConcurrencyTimeout test that queued-but-not-yet-started
+// tasks are interrupted by a timeout
+func Test_WaitGroup_MaxConcurrencyTimeout(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(slowFunc, slowFunc, slowFunc, slowFunc)
+	wg.SetMaxConcurrency(1).SetTimeout(time.Millisecond).Start()
+
+	errs := wg.GetAllErrors()
+	if len(errs) == 0 {
+		t.Fatal("WaitGroup should stop with error by timeout!")
+	}
+
+	var foundTimeout bool
+	for _, err := range errs {
+		if _, ok := err.(ErrorTimeout); ok {
+			foundTimeout = true
+		}
+	}
+
+	if !foundTimeout {
+		t.Errorf("Expected an ErrorTimeout among the errors, got %v", errs)
+	}
+}
+
+// Test_WaitGroup_MaxConcurrencyTimeoutNotStarted test that a task still
+// queued behind SetMaxConcurrency when the group times out is reported with
+// ErrTaskNotStarted, and that the result actually reaches the stream
+func Test_WaitGroup_MaxConcurrencyTimeoutNotStarted(t *testing.T) {
+	var wg waitGroup
+
+	holding := make(chan struct{})
+	hold := func(context.Context) error {
+		<-holding
+		return nil
+	}
+
+	wg.Add(hold, fastFunc, fastFunc, fastFunc)
+	wg.SetMaxConcurrency(1).SetTimeout(10 * time.Millisecond)
+
+	var results []TaskResult
+	for res := range wg.StartStream() {
+		results = append(results, res)
+	}
+
+	close(holding)
+
+	var notStarted int
+	for _, res := range results {
+		if errors.Is(res.Err, ErrTaskNotStarted) {
+			notStarted++
+		}
+	}
+
+	if notStarted == 0 {
+		t.Error("Expected at least one task queued behind the semaphore to report ErrTaskNotStarted")
+	}
+
+	var foundInErrors bool
+	for _, err := range wg.GetAllErrors() {
+		if errors.Is(err, ErrTaskNotStarted) {
+			foundInErrors = true
+		}
+	}
+
+	if !foundInErrors {
+		t.Error("Expected ErrTaskNotStarted to also surface via GetAllErrors")
+	}
+}
+
+// Test_WaitGroup_AddWithRetry test that a failing task eventually succeeds
+// after enough attempts
+func Test_WaitGroup_AddWithRetry(t *testing.T) {
+	var wg waitGroup
+
+	var calls int
+	flaky := func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("Test error")
+		}
+		return nil
+	}
+
+	wg.AddWithRetry(5, ConstantBackoff(time.Millisecond), flaky)
+	wg.Start()
+
+	if err := wg.GetLastError(); err != nil {
+		t.Errorf("Task should have succeeded after retries, got %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+// Test_WaitGroup_AddWithRetryExhausted test that the final error surfaces
+// once attempts are exhausted
+func Test_WaitGroup_AddWithRetryExhausted(t *testing.T) {
+	var wg waitGroup
+
+	wg.AddWithRetry(2, ConstantBackoff(time.Millisecond), errorFunc)
+	wg.Start()
+
+	if err := wg.GetLastError(); err == nil {
+		t.Error("Task should fail once attempts are exhausted")
+	}
+}
+
+// Test_WaitGroup_AddWithRetryZeroAttempts test that attempts <= 0 still runs
+// f once instead of silently dropping the task
+func Test_WaitGroup_AddWithRetryZeroAttempts(t *testing.T) {
+	var wg waitGroup
+
+	var calls int32
+	f := func(context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("Test error")
+	}
+
+	wg.AddWithRetry(0, ConstantBackoff(time.Millisecond), f)
+	wg.Start()
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected f to run once, ran %d times", calls)
+	}
+
+	if errs := wg.GetAllErrors(); len(errs) != 1 {
+		t.Errorf("Expected the task's error to surface, got %v", errs)
+	}
+}
+
+// Test_WaitGroup_SetRetry test the group-wide retry policy
+func Test_WaitGroup_SetRetry(t *testing.T) {
+	var wg waitGroup
+
+	var calls int
+	flaky := func(context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("Test error")
+		}
+		return nil
+	}
+
+	wg.Add(flaky)
+	wg.SetRetry(3, ConstantBackoff(time.Millisecond)).Start()
+
+	if err := wg.GetLastError(); err != nil {
+		t.Errorf("Task should have succeeded after retries, got %v", err)
+	}
+}
+
+// Test_WaitGroup_AddWithRetryPanic test that a panic counts as an attempt
+// and doesn't abort the retry loop
+func Test_WaitGroup_AddWithRetryPanic(t *testing.T) {
+	var wg waitGroup
+
+	var calls int
+	flakyPanic := func(context.Context) error {
+		calls++
+		if calls < 2 {
+			panic("Test expected panic, it's ok ;)")
+		}
+		return nil
+	}
+
+	wg.AddWithRetry(3, ConstantBackoff(time.Millisecond), flakyPanic)
+	wg.Start()
+
+	if err := wg.GetLastError(); err != nil {
+		t.Errorf("Task should have succeeded after retries, got %v", err)
+	}
+}
+
+// Test_WaitGroup_AddKeyed test that duplicate keys only run f once
+func Test_WaitGroup_AddKeyed(t *testing.T) {
+	var wg waitGroup
+
+	var calls int32
+	f := func(context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+
+	for i := 0; i < 5; i++ {
+		wg.AddKeyed("shared", f)
+	}
+
+	wg.Start()
+
+	if errs := wg.GetAllErrors(); len(errs) != 0 {
+		t.Errorf("Shouldn`t get errors, got %v", errs)
+	}
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected f to run once, ran %d times", calls)
+	}
+}
+
+// Test_WaitGroup_AddKeyedError test that duplicates share the same error
+func Test_WaitGroup_AddKeyedError(t *testing.T) {
+	var wg waitGroup
+
+	wg.AddKeyed("shared", errorFunc)
+	wg.AddKeyed("shared", errorFunc)
+	wg.AddKeyed("shared", errorFunc)
+
+	wg.Start()
+
+	if errs := wg.GetAllErrors(); len(errs) != 3 {
+		t.Errorf("Expected all three submissions to share the error, got %v", errs)
+	}
+}
+
+// Test_WaitGroup_AddKeyedReRuns test that a key runs again once its
+// in-flight call has finished
+func Test_WaitGroup_AddKeyedReRuns(t *testing.T) {
+	var wg waitGroup
+
+	var calls int32
+	f := func(context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+
+	wg.AddKeyed("shared", f)
+	wg.Start()
+
+	wg.Reset()
+	wg.AddKeyed("shared", f)
+	wg.Start()
+
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected f to run twice across batches, ran %d times", calls)
+	}
+}
+
+// Test_WaitGroup_AddKeyedWithMaxConcurrency test that dedup still holds when
+// SetMaxConcurrency forces a duplicate submission to queue behind the
+// in-flight call instead of running alongside it
+func Test_WaitGroup_AddKeyedWithMaxConcurrency(t *testing.T) {
+	var wg waitGroup
+	wg.SetMaxConcurrency(1)
+
+	var calls int32
+	f := func(context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+
+	wg.AddKeyed("shared", f)
+	wg.AddKeyed("shared", f)
+
+	wg.Start()
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected f to run once despite SetMaxConcurrency, ran %d times", calls)
+	}
+}
+
+// Test_WaitGroup_StartStream test that results are streamed as tasks finish
+func Test_WaitGroup_StartStream(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(fastFunc, fastFunc, errorFunc)
+
+	var received []TaskResult
+	for res := range wg.StartStream() {
+		received = append(received, res)
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 streamed results, got %d", len(received))
+	}
+
+	var errCount int
+	for _, res := range received {
+		if res.Err != nil {
+			errCount++
+		}
+	}
+
+	if errCount != 1 {
+		t.Errorf("Expected 1 streamed error, got %d", errCount)
+	}
+
+	if errs := wg.GetAllErrors(); len(errs) != 1 {
+		t.Errorf("GetAllErrors should stay consistent after the stream is drained, got %v", errs)
+	}
+}
+
+// Test_WaitGroup_StartStreamEmpty test that an empty group closes the stream
+// right away
+func Test_WaitGroup_StartStreamEmpty(t *testing.T) {
+	var wg waitGroup
+
+	received := 0
+	for range wg.StartStream() {
+		received++
+	}
+
+	if received != 0 {
+		t.Errorf("Expected no streamed results, got %d", received)
+	}
+}
+
+// Test_WaitGroup_AddCleanup test that cleanups run after all tasks finish
+func Test_WaitGroup_AddCleanup(t *testing.T) {
+	var wg waitGroup
+
+	var ran bool
+	wg.Add(fastFunc, fastFunc)
+	wg.AddCleanup(func(context.Context) error {
+		ran = true
+		return nil
+	})
+
+	wg.Start()
+
+	if !ran {
+		t.Error("Cleanup should have run after all tasks finished")
+	}
+}
+
+// Test_WaitGroup_AddCleanupError test that cleanup errors are tagged and
+// surfaced via GetAllErrors
+func Test_WaitGroup_AddCleanupError(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(fastFunc)
+	wg.AddCleanup(func(context.Context) error {
+		return errors.New("Test cleanup error")
+	})
+
+	wg.Start()
+
+	err := wg.GetLastError()
+	if err == nil {
+		t.Fatal("Cleanup error should be reported")
+	}
+
+	if _, ok := err.(CleanupError); !ok {
+		t.Errorf("Wrong error type. Got %[1]T: %[1]q", err)
+	}
+}
+
+// Test_WaitGroup_AddCleanupOnTimeout test that cleanups still run when the
+// group is broken by timeout
+func Test_WaitGroup_AddCleanupOnTimeout(t *testing.T) {
+	var wg waitGroup
+
+	var ran bool
+	wg.Add(slowFunc, slowFunc)
+	wg.AddCleanup(func(context.Context) error {
+		ran = true
+		return nil
+	})
+
+	wg.SetTimeout(time.Nanosecond).Start()
+
+	if !ran {
+		t.Error("Cleanup should have run even though the group timed out")
+	}
+}
+
+// Test_WaitGroup_AddCleanupSurvivesCancelledContext test that a cancelled
+// parent context doesn't prevent cleanups from completing
+func Test_WaitGroup_AddCleanupSurvivesCancelledContext(t *testing.T) {
+	var wg waitGroup
+
+	var ran bool
+	wg.Add(fastFunc)
+	wg.AddCleanup(func(ctx context.Context) error {
+		ran = true
+		if err := ctx.Err(); err != nil {
+			t.Errorf("Cleanup context shouldn`t already be done, got %v", err)
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	wg.WithContext(ctx).Start()
+
+	if !ran {
+		t.Error("Cleanup should run against its own fresh context")
+	}
+}
+
 var results = make(chan bool, 100)
 
 func fastFuncWithResult(context.Context) error {
@@ -503,3 +1024,79 @@ func Test_WaitGroup_Timeout_Execution(t *testing.T) {
 	//Debug
 	t.Logf("Done %v of %v", count, maxProcs)
 }
+
+// Test_WaitGroup_Wait test for errgroup-style Wait
+func Test_WaitGroup_Wait(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(fastFunc, errorFunc)
+
+	if err := wg.Wait(); err == nil {
+		t.Error("Wait should return the first caught error")
+	}
+}
+
+// Test_WaitGroup_WaitSuccess test for errgroup-style Wait without errors
+func Test_WaitGroup_WaitSuccess(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(fastFunc, fastFunc)
+
+	if err := wg.Wait(); err != nil {
+		t.Errorf("Wait shouldn`t return an error, got %v", err)
+	}
+}
+
+// Test_WaitGroup_GoBeforeStart test that Go behaves like Add before Start
+func Test_WaitGroup_GoBeforeStart(t *testing.T) {
+	var wg waitGroup
+
+	wg.Go(fastFunc)
+	wg.Go(errorFunc)
+
+	if err := wg.Wait(); err == nil {
+		t.Error("Wait should return the error queued via Go")
+	}
+}
+
+// Test_WaitGroup_GoAfterStart test that Go launches a task while Start is
+// already running
+func Test_WaitGroup_GoAfterStart(t *testing.T) {
+	var wg waitGroup
+
+	wg.Add(slowFunc, slowFunc)
+
+	chDone := make(chan struct{})
+	go func() {
+		wg.Start()
+		chDone <- struct{}{}
+	}()
+
+	wg.Go(errorFunc)
+	<-chDone
+
+	if err := wg.GetLastError(); err == nil {
+		t.Error("WaitGroup should catch the error from a task added via Go")
+	}
+}
+
+// Test_WaitGroup_GoAfterFinish hammers Go() right as Start() is finishing, to
+// catch the TOCTOU window where Go() could observe the group as still
+// running a moment before runLoop closes the sender channel, and then panic
+// sending on it
+func Test_WaitGroup_GoAfterFinish(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		var wg waitGroup
+
+		wg.Add(fastFunc)
+
+		chDone := make(chan struct{})
+		go func() {
+			wg.Start()
+			close(chDone)
+		}()
+
+		wg.Go(fastFunc)
+		<-chDone
+	}
+}